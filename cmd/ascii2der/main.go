@@ -0,0 +1,66 @@
+// Copyright 2015 The DER ASCII Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ascii2der translates the human-readable ASCII syntax der2ascii produces
+// back into DER-encoded data.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/google/der-ascii/lib"
+)
+
+var pemLabel = flag.String("pem", "", "if non-empty, armor the output as PEM with this label (e.g. CERTIFICATE) instead of writing raw DER")
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-pem LABEL] FILE\n", os.Args[0])
+		os.Exit(1)
+	}
+
+	in, err := ioutil.ReadFile(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	in, err = expandISOTimeLiterals(in)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	in, err = expandStringLiterals(in)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	der, err := lib.Compile(in)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *pemLabel != "" {
+		os.Stdout.Write(wrapPEM(der, *pemLabel))
+	} else {
+		os.Stdout.Write(der)
+	}
+}
@@ -0,0 +1,24 @@
+// Copyright 2015 The DER ASCII Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "encoding/pem"
+
+// wrapPEM armors der in a PEM block labeled with label (e.g. "CERTIFICATE"),
+// producing the output for the -pem flag. It is the inverse of the PEM
+// stripping der2ascii performs on its input.
+func wrapPEM(der []byte, label string) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: label, Bytes: der})
+}
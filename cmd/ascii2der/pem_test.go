@@ -0,0 +1,39 @@
+// Copyright 2015 The DER ASCII Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/pem"
+	"testing"
+)
+
+func TestWrapPEM(t *testing.T) {
+	der := []byte{0x02, 0x01, 0x01}
+	out := wrapPEM(der, "CERTIFICATE")
+
+	block, rest := pem.Decode(out)
+	if block == nil {
+		t.Fatalf("wrapPEM produced unparseable PEM: %q", out)
+	}
+	if len(rest) != 0 {
+		t.Errorf("wrapPEM left trailing data: %q", rest)
+	}
+	if block.Type != "CERTIFICATE" {
+		t.Errorf("wrapPEM block type = %q; want CERTIFICATE", block.Type)
+	}
+	if string(block.Bytes) != string(der) {
+		t.Errorf("wrapPEM round-tripped to %x; want %x", block.Bytes, der)
+	}
+}
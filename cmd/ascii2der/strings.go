@@ -0,0 +1,132 @@
+// Copyright 2015 The DER ASCII Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+)
+
+// stringLiteral matches a u"...", b"...", or U"..." literal as written by
+// der2ascii's utf8StringToString, bmpStringToString, and
+// universalStringToString: a prefix naming the encoding, followed by a
+// quoted string using the same escapes quoteRunes produces (\n, \", \\, and
+// \x{hex} for any rune not written literally).
+var stringLiteral = regexp.MustCompile(`([ubU])"((?:[^"\\]|\\.)*)"`)
+
+// decodeQuotedRunes reverses quoteRunes's escaping, returning the runes the
+// quoted content s (with its surrounding quotes already stripped) encodes.
+func decodeQuotedRunes(s string) ([]rune, error) {
+	b := []byte(s)
+	var runes []rune
+	for i := 0; i < len(b); i++ {
+		if b[i] != '\\' {
+			r, size := utf8.DecodeRune(b[i:])
+			runes = append(runes, r)
+			i += size - 1
+			continue
+		}
+		i++
+		if i >= len(b) {
+			return nil, fmt.Errorf("unterminated escape in %q", s)
+		}
+		switch b[i] {
+		case 'n':
+			runes = append(runes, '\n')
+		case '"', '\\':
+			runes = append(runes, rune(b[i]))
+		case 'x':
+			i++
+			if i >= len(b) || b[i] != '{' {
+				return nil, fmt.Errorf("malformed \\x escape in %q", s)
+			}
+			i++
+			start := i
+			for i < len(b) && b[i] != '}' {
+				i++
+			}
+			if i >= len(b) {
+				return nil, fmt.Errorf("unterminated \\x{...} escape in %q", s)
+			}
+			v, err := strconv.ParseUint(s[start:i], 16, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid \\x{...} escape in %q: %s", s, err)
+			}
+			runes = append(runes, rune(v))
+		default:
+			return nil, fmt.Errorf("unknown escape \\%c in %q", b[i], s)
+		}
+	}
+	return runes, nil
+}
+
+// stringLiteralToBytes decodes content (a u"...", b"...", or U"..."
+// literal's quoted content, prefix already stripped) to the raw body bytes
+// for a UTF8String ('u'), BMPString ('b'), or UniversalString ('U').
+func stringLiteralToBytes(prefix byte, content string) ([]byte, error) {
+	runes, err := decodeQuotedRunes(content)
+	if err != nil {
+		return nil, err
+	}
+	switch prefix {
+	case 'u':
+		return []byte(string(runes)), nil
+	case 'b':
+		out := make([]byte, 0, len(runes)*2)
+		for _, r := range runes {
+			if r > 0xffff {
+				return nil, fmt.Errorf("rune %#x does not fit in a BMPString code unit", r)
+			}
+			out = append(out, byte(r>>8), byte(r))
+		}
+		return out, nil
+	case 'U':
+		out := make([]byte, 0, len(runes)*4)
+		for _, r := range runes {
+			out = append(out, byte(r>>24), byte(r>>16), byte(r>>8), byte(r))
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unknown string literal prefix %q", prefix)
+}
+
+// expandStringLiterals rewrites every u"...", b"...", and U"..." literal in
+// src to a plain hex literal holding the bytes it encodes, so ascii2der's
+// existing grammar (which already understands hex literals) does not need
+// to learn a new string syntax. This is a textual preprocessing pass run
+// before the rest of ascii2der sees src, the same approach
+// expandISOTimeLiterals takes for time literals.
+func expandStringLiterals(src []byte) ([]byte, error) {
+	var firstErr error
+	out := stringLiteral.ReplaceAllFunc(src, func(match []byte) []byte {
+		if firstErr != nil {
+			return match
+		}
+		groups := stringLiteral.FindSubmatch(match)
+		body, err := stringLiteralToBytes(groups[1][0], string(groups[2]))
+		if err != nil {
+			firstErr = err
+			return match
+		}
+		return []byte("`" + hex.EncodeToString(body) + "`")
+	})
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return out, nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2015 The DER ASCII Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestExpandStringLiterals(t *testing.T) {
+	tests := []struct {
+		src, wantHex string
+	}{
+		{`u"hi"`, "6869"},
+		{`u"h\x{e9}llo"`, "68c3a96c6c6f"}, // h, é (U+00E9, UTF-8 c3 a9), llo
+		{`b"AB"`, "00410042"},             // big-endian UCS-2 code units
+		{`U"AB"`, "0000004100000042"},     // big-endian UCS-4 code units
+	}
+	for _, tt := range tests {
+		got, err := expandStringLiterals([]byte(tt.src))
+		if err != nil {
+			t.Errorf("expandStringLiterals(%s): %s", tt.src, err)
+			continue
+		}
+		want := "`" + tt.wantHex + "`"
+		if string(got) != want {
+			t.Errorf("expandStringLiterals(%s) = %s; want %s", tt.src, got, want)
+		}
+	}
+
+	// Input with no recognized literal passes through unchanged.
+	src := `INTEGER { 1 }`
+	got, err := expandStringLiterals([]byte(src))
+	if err != nil || string(got) != src {
+		t.Errorf("expandStringLiterals(no literal) = %q, %v; want unchanged input", got, err)
+	}
+
+	if _, err := expandStringLiterals([]byte(`u"bad \x{zz} escape"`)); err == nil {
+		t.Error("expandStringLiterals accepted a malformed \\x{...} escape")
+	}
+}
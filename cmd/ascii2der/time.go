@@ -0,0 +1,70 @@
+// Copyright 2015 The DER ASCII Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// encodeASN1Time parses iso, an ISO-8601-like literal such as
+// "2023-01-15T12:00:00Z", and re-encodes it to the canonical DER body for a
+// UTCTime ("230115120000Z") or GeneralizedTime ("20230115120000Z"). This is
+// the inverse of der2ascii's human-readable time comment, and lets ascii
+// source name a time without having to hand-compute the packed digit
+// string.
+func encodeASN1Time(iso string, generalized bool) ([]byte, error) {
+	t, err := time.Parse(time.RFC3339, iso)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time literal %q: %s", iso, err)
+	}
+	t = t.UTC()
+	if generalized {
+		return []byte(t.Format("20060102150405Z")), nil
+	}
+	return []byte(t.Format("060102150405Z")), nil
+}
+
+// isoTimeLiteral matches an iso"..." literal immediately inside a UTCTime
+// or GeneralizedTime element, e.g. `UTCTime { iso"2023-01-15T12:00:00Z" }`.
+var isoTimeLiteral = regexp.MustCompile(`(UTCTime|GeneralizedTime)(\s*\{\s*)iso"([^"]*)"`)
+
+// expandISOTimeLiterals rewrites every iso"..." literal in src to the
+// quoted, packed digit string its UTCTime or GeneralizedTime element
+// actually encodes, so ascii source can name a validity date without the
+// author hand-computing "230115120000Z" themselves. It is a textual
+// preprocessing pass over src, run before the rest of ascii2der sees it, so
+// the grammar itself does not need to know about ISO-8601.
+func expandISOTimeLiterals(src []byte) ([]byte, error) {
+	var firstErr error
+	out := isoTimeLiteral.ReplaceAllFunc(src, func(match []byte) []byte {
+		if firstErr != nil {
+			return match
+		}
+		groups := isoTimeLiteral.FindSubmatch(match)
+		generalized := string(groups[1]) == "GeneralizedTime"
+		body, err := encodeASN1Time(string(groups[3]), generalized)
+		if err != nil {
+			firstErr = err
+			return match
+		}
+		return append(append(append([]byte{}, groups[1]...), groups[2]...), []byte(`"`+string(body)+`"`)...)
+	})
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return out, nil
+}
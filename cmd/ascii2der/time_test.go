@@ -0,0 +1,60 @@
+// Copyright 2015 The DER ASCII Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestEncodeASN1Time(t *testing.T) {
+	body, err := encodeASN1Time("2023-01-15T12:00:00Z", false)
+	if err != nil || string(body) != "230115120000Z" {
+		t.Errorf("encodeASN1Time(UTCTime) = %q, %v; want 230115120000Z, nil", body, err)
+	}
+
+	body, err = encodeASN1Time("2023-01-15T12:00:00Z", true)
+	if err != nil || string(body) != "20230115120000Z" {
+		t.Errorf("encodeASN1Time(GeneralizedTime) = %q, %v; want 20230115120000Z, nil", body, err)
+	}
+
+	if _, err := encodeASN1Time("not a time", false); err == nil {
+		t.Error("encodeASN1Time accepted a malformed literal")
+	}
+}
+
+func TestExpandISOTimeLiterals(t *testing.T) {
+	src := `UTCTime { iso"2023-01-15T12:00:00Z" }`
+	want := `UTCTime { "230115120000Z" }`
+	got, err := expandISOTimeLiterals([]byte(src))
+	if err != nil || string(got) != want {
+		t.Errorf("expandISOTimeLiterals(UTCTime) = %q, %v; want %q, nil", got, err, want)
+	}
+
+	src = `GeneralizedTime { iso"2023-01-15T12:00:00Z" }`
+	want = `GeneralizedTime { "20230115120000Z" }`
+	got, err = expandISOTimeLiterals([]byte(src))
+	if err != nil || string(got) != want {
+		t.Errorf("expandISOTimeLiterals(GeneralizedTime) = %q, %v; want %q, nil", got, err, want)
+	}
+
+	// Input with no iso literal passes through unchanged.
+	src = `INTEGER { 1 }`
+	got, err = expandISOTimeLiterals([]byte(src))
+	if err != nil || string(got) != src {
+		t.Errorf("expandISOTimeLiterals(no literal) = %q, %v; want unchanged input", got, err)
+	}
+
+	if _, err := expandISOTimeLiterals([]byte(`UTCTime { iso"not a time" }`)); err == nil {
+		t.Error("expandISOTimeLiterals accepted a malformed iso literal")
+	}
+}
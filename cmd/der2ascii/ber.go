@@ -0,0 +1,123 @@
+// Copyright 2015 The DER ASCII Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/google/der-ascii/lib"
+)
+
+// tagHeaderLen returns the number of bytes used to encode tag's identifier
+// octets, per X.690 8.1.2. This is the same for BER and DER.
+func tagHeaderLen(tag lib.Tag) int {
+	if tag.Number < 31 {
+		return 1
+	}
+	n := 1
+	for v := tag.Number; v > 0; v >>= 7 {
+		n++
+	}
+	return n
+}
+
+// minimalLengthLen returns the number of bytes the minimal (DER) length
+// encoding of a body of n bytes requires, per X.690 8.1.3.
+func minimalLengthLen(n int) int {
+	if n < 128 {
+		return 1
+	}
+	l := 1
+	for v := n; v > 0; v >>= 8 {
+		l++
+	}
+	return l
+}
+
+// derToASCIIBER behaves like derToASCII, but for BER rather than strictly
+// DER input. It does not itself need to relax parseElement's DER checks on
+// non-minimal lengths and non-canonical BOOLEANs, since both already
+// survive disassembly today: a non-canonical BOOLEAN body falls back to
+// der-ascii's hex literal, and a constructed OCTET STRING or BIT STRING
+// already disassembles its segments as explicit child elements rather than
+// concatenating them. What BER mode adds on top is calling out non-minimal
+// ("long-form") length encodings with a leading comment and writing such an
+// element as a hex literal of its exact bytes, so that information is not
+// silently lost the way it would be if the length were simply re-derived
+// from the body on re-encoding.
+func derToASCIIBER(in []byte) string {
+	var out bytes.Buffer
+	derToASCIIBERImpl(&out, in, 0, false)
+	return out.String()
+}
+
+// derToASCIIBERImpl is the BER-aware counterpart to derToASCIIImpl. For a
+// constructed element, it recurses so long-form-length comments are found
+// at every depth; for a leaf element (or one with an empty body), it defers
+// to derToASCIIImpl for the actual line, so BER and DER mode render leaf
+// elements identically. Unlike DER mode, an element with a non-minimal
+// length is written as a hex literal after its comment, and the X.509
+// structure hints are not applied between sibling elements.
+func derToASCIIBERImpl(out io.Writer, in []byte, indent int, stopAtEOC bool) []byte {
+	for len(in) != 0 {
+		if stopAtEOC && len(in) >= 2 && in[0] == 0 && in[1] == 0 {
+			// Emit a `0000` in lieu of a closing base.
+			addLine(out, indent-1, "`0000`")
+			return in[2:]
+		}
+
+		tag, body, indefinite, rest, ok := parseElement(in)
+		if !ok {
+			addLine(out, indent, bytesToString(in))
+			return nil
+		}
+
+		if indefinite {
+			addLine(out, indent, fmt.Sprintf("%s `80`", tagToString(tag)))
+			in = derToASCIIBERImpl(out, rest, indent+1, true)
+			continue
+		}
+
+		elementLen := len(in) - len(rest)
+		lengthLen := elementLen - tagHeaderLen(tag) - len(body)
+		if lengthLen > minimalLengthLen(len(body)) {
+			// A non-minimal length encoding: re-encoding the body
+			// on its own would shrink it back to the minimal form
+			// and silently change the bytes on the wire. Preserve
+			// the element exactly as a hex literal rather than
+			// losing that information to a comment.
+			addLine(out, indent, fmt.Sprintf("# long-form length (%d octets)", lengthLen))
+			addLine(out, indent, bytesToString(in[:elementLen]))
+			in = rest
+			continue
+		}
+
+		if tag.Constructed && len(body) > 0 {
+			addLine(out, indent, fmt.Sprintf("%s {", tagToString(tag)))
+			derToASCIIBERImpl(out, body, indent+1, false)
+			addLine(out, indent, "}")
+			in = rest
+			continue
+		}
+
+		// A primitive element, or a constructed one with an empty
+		// body: render it exactly as DER mode would.
+		derToASCIIImpl(out, in[:elementLen], indent, false)
+		in = rest
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2015 The DER ASCII Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/der-ascii/lib"
+)
+
+func TestTagHeaderLen(t *testing.T) {
+	if got := tagHeaderLen(lib.Tag{Class: lib.ClassUniversal, Number: 2}); got != 1 {
+		t.Errorf("tagHeaderLen(low tag number) = %d; want 1", got)
+	}
+	if got := tagHeaderLen(lib.Tag{Class: lib.ClassContextSpecific, Number: 31}); got != 2 {
+		t.Errorf("tagHeaderLen(high tag number 31) = %d; want 2", got)
+	}
+}
+
+func TestMinimalLengthLen(t *testing.T) {
+	tests := []struct {
+		n    int
+		want int
+	}{
+		{0, 1},
+		{127, 1},
+		{128, 2},
+		{255, 2},
+		{256, 3},
+		{65535, 3},
+		{65536, 4},
+	}
+	for _, tt := range tests {
+		if got := minimalLengthLen(tt.n); got != tt.want {
+			t.Errorf("minimalLengthLen(%d) = %d; want %d", tt.n, got, tt.want)
+		}
+	}
+}
+
+// TestDerToASCIIBERLongForm is a regression test for a length-octet-count
+// bug: headerLen - tagHeaderLen(tag) once included the body length itself,
+// so almost every non-empty element was misreported as long-form.
+func TestDerToASCIIBERLongForm(t *testing.T) {
+	// INTEGER { 5 }, minimal length encoding: no comment expected.
+	minimal := []byte{0x02, 0x01, 0x05}
+	if got := derToASCIIBER(minimal); strings.Contains(got, "long-form") {
+		t.Errorf("derToASCIIBER(minimal length) = %q; want no long-form comment", got)
+	}
+
+	// INTEGER { 5 }, but with a non-minimal 2-octet long-form length
+	// (0x81 0x01) where a single short-form octet (0x01) would do.
+	longForm := []byte{0x02, 0x81, 0x01, 0x05}
+	got := derToASCIIBER(longForm)
+	if !strings.Contains(got, "long-form length (2 octets)") {
+		t.Errorf("derToASCIIBER(long-form length) = %q; want a long-form comment", got)
+	}
+	// The element must still be preserved byte-for-byte as a hex literal,
+	// not just noted in a comment and then re-minimized.
+	if !strings.Contains(got, "`02810105`") {
+		t.Errorf("derToASCIIBER(long-form length) = %q; want the raw element preserved as hex", got)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2015 The DER ASCII Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// der2ascii translates DER-encoded data into a human-readable ASCII syntax
+// that ascii2der can translate back. Input is read from a file named on the
+// command line, or from stdin if none is given; PEM-armored input is
+// detected automatically and unwrapped before disassembly, except with -ber,
+// which disassembles its input as raw BER.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+var ber = flag.Bool("ber", false, "call out non-minimal BER length encodings rather than assuming strict DER")
+
+func main() {
+	flag.Parse()
+	if flag.NArg() > 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-ber] [FILE]\n", os.Args[0])
+		os.Exit(1)
+	}
+
+	var in []byte
+	var err error
+	if flag.NArg() == 1 {
+		in, err = ioutil.ReadFile(flag.Arg(0))
+	} else {
+		in, err = ioutil.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *ber {
+		fmt.Print(derToASCIIBER(in))
+	} else {
+		fmt.Print(derToASCIIPEM(in))
+	}
+}
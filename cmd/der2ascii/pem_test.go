@@ -0,0 +1,50 @@
+// Copyright 2015 The DER ASCII Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLooksLikePEM(t *testing.T) {
+	if !looksLikePEM([]byte("-----BEGIN CERTIFICATE-----\n...")) {
+		t.Error("looksLikePEM rejected a PEM-armored input")
+	}
+	if !looksLikePEM([]byte("\n\n-----BEGIN CERTIFICATE-----\n...")) {
+		t.Error("looksLikePEM rejected leading whitespace before the armor")
+	}
+	if looksLikePEM([]byte{0x30, 0x03, 0x02, 0x01, 0x01}) {
+		t.Error("looksLikePEM accepted raw DER")
+	}
+}
+
+func TestDerToASCIIPEM(t *testing.T) {
+	// SEQUENCE { INTEGER { 1 } }, PEM-armored under an INTEGER label.
+	const block = "-----BEGIN INTEGER-----\nMAMCAQE=\n-----END INTEGER-----\n"
+	got := derToASCIIPEM([]byte(block))
+	if !strings.Contains(got, "# PEM: INTEGER") {
+		t.Errorf("derToASCIIPEM(block) = %q; want a leading PEM label comment", got)
+	}
+	if !strings.Contains(got, "INTEGER { 1 }") {
+		t.Errorf("derToASCIIPEM(block) = %q; want the disassembled contents", got)
+	}
+
+	// Non-PEM input passes through to derToASCII unchanged.
+	raw := []byte{0x02, 0x01, 0x01}
+	if got, want := derToASCIIPEM(raw), derToASCII(raw); got != want {
+		t.Errorf("derToASCIIPEM(raw DER) = %q; want %q", got, want)
+	}
+}
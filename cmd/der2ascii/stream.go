@@ -0,0 +1,163 @@
+// Copyright 2015 The DER ASCII Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/google/der-ascii/lib"
+)
+
+// readHeader reads a single DER tag and length from r, per X.690 8.1.2 and
+// 8.1.3, returning the parsed tag and the declared body length. Unlike
+// parseElement, it does not require the body to already be in memory.
+// Indefinite lengths are not supported: streaming disassembly targets the
+// common case of well-formed DER (CMS blobs, timestamp archives), which
+// never uses them.
+//
+// Only EOF on the very first byte of a header is a legitimate end of input;
+// that is the sole case readHeader returns io.EOF itself. EOF reached after
+// that point means the stream was truncated mid-tag-number or
+// mid-length-octets, which readHeader reports as io.ErrUnexpectedEOF so
+// callers don't mistake a corrupted stream for a clean one.
+func readHeader(r *bufio.Reader) (tag lib.Tag, length int, err error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return lib.Tag{}, 0, err
+	}
+
+	// readByte is like r.ReadByte, but every call after the header's first
+	// byte: reaching EOF here means the header was cut off partway through,
+	// not that input legitimately ended between elements.
+	readByte := func() (byte, error) {
+		b, err := r.ReadByte()
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return b, err
+	}
+
+	switch b & 0xc0 {
+	case 0x00:
+		tag.Class = lib.ClassUniversal
+	case 0x40:
+		tag.Class = lib.ClassApplication
+	case 0x80:
+		tag.Class = lib.ClassContextSpecific
+	case 0xc0:
+		tag.Class = lib.ClassPrivate
+	}
+	tag.Constructed = b&0x20 != 0
+
+	if number := uint32(b & 0x1f); number < 31 {
+		tag.Number = number
+	} else {
+		tag.Number = 0
+		for {
+			b, err = readByte()
+			if err != nil {
+				return lib.Tag{}, 0, err
+			}
+			tag.Number = tag.Number<<7 | uint32(b&0x7f)
+			if b&0x80 == 0 {
+				break
+			}
+		}
+	}
+
+	lb, err := readByte()
+	if err != nil {
+		return lib.Tag{}, 0, err
+	}
+	if lb == 0x80 {
+		return lib.Tag{}, 0, fmt.Errorf("streaming disassembly does not support indefinite lengths")
+	}
+	if lb < 0x80 {
+		return tag, int(lb), nil
+	}
+	length = 0
+	for i := 0; i < int(lb&0x7f); i++ {
+		b, err = readByte()
+		if err != nil {
+			return lib.Tag{}, 0, err
+		}
+		length = length<<8 | int(b)
+	}
+	return tag, length, nil
+}
+
+// writeElementsStream reads elements from r until EOF, writing each to out
+// at the given indent. A constructed element's body is itself read through
+// a fresh bufio.Reader bounded to its declared length by an
+// io.LimitedReader, so at any one time only the element currently being
+// read is buffered, not the whole input. A body that ends before its
+// declared length is reported as io.ErrUnexpectedEOF.
+func writeElementsStream(out io.Writer, r *bufio.Reader, indent int) error {
+	for {
+		tag, length, err := readHeader(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if tag.Constructed && length != 0 {
+			addLine(out, indent, fmt.Sprintf("%s {", tagToString(tag)))
+			limited := &io.LimitedReader{R: r, N: int64(length)}
+			child := bufio.NewReader(limited)
+			if err := writeElementsStream(out, child, indent+1); err != nil {
+				return err
+			}
+			if limited.N != 0 {
+				// The input ended before the declared length did.
+				return io.ErrUnexpectedEOF
+			}
+			addLine(out, indent, "}")
+			continue
+		}
+
+		if length == 0 {
+			addLine(out, indent, fmt.Sprintf("%s {}", tagToString(tag)))
+			continue
+		}
+
+		body := make([]byte, length)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return err
+		}
+		name, _, _ := tag.GetAlias()
+		writePrimitive(out, tag, name, body, indent)
+	}
+}
+
+// DerToASCIIStream disassembles the DER-encoded data read from r, writing
+// each line to w as it is produced rather than building the entire
+// disassembly in memory first, and reading r through fixed-size buffers
+// rather than all at once. This keeps memory use
+// proportional to nesting depth and the size of the largest single element,
+// rather than the size of the whole input, for multi-hundred-megabyte CMS
+// or timestamp archives. Unlike derToASCII, it does not apply the X.509
+// structure hints, since those need to see a whole extension's sibling
+// elements at once, nor does it accept BER input.
+func DerToASCIIStream(r io.Reader, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if err := writeElementsStream(bw, bufio.NewReader(r), 0); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,108 @@
+// Copyright 2015 The DER ASCII Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/google/der-ascii/lib"
+)
+
+func TestReadHeader(t *testing.T) {
+	// INTEGER, length 5.
+	r := bufio.NewReader(bytes.NewReader([]byte{0x02, 0x05}))
+	tag, length, err := readHeader(r)
+	if err != nil {
+		t.Fatalf("readHeader: %s", err)
+	}
+	if tag.Class != lib.ClassUniversal || tag.Constructed || tag.Number != 2 || length != 5 {
+		t.Errorf("readHeader = %+v, %d; want {Universal false 2}, 5", tag, length)
+	}
+}
+
+func TestReadHeaderLongFormLength(t *testing.T) {
+	// OCTET STRING, a 2-octet long-form length encoding 300.
+	r := bufio.NewReader(bytes.NewReader([]byte{0x04, 0x82, 0x01, 0x2c}))
+	tag, length, err := readHeader(r)
+	if err != nil {
+		t.Fatalf("readHeader: %s", err)
+	}
+	if tag.Number != 4 || length != 300 {
+		t.Errorf("readHeader = %+v, %d; want {... 4}, 300", tag, length)
+	}
+}
+
+func TestReadHeaderCleanEOF(t *testing.T) {
+	// Nothing at all: a legitimate end of input between elements.
+	r := bufio.NewReader(bytes.NewReader(nil))
+	if _, _, err := readHeader(r); err != io.EOF {
+		t.Errorf("readHeader(empty) err = %v; want io.EOF", err)
+	}
+}
+
+// TestReadHeaderTruncated is a regression test: a stream cut off partway
+// through a header (here, a high-tag-number continuation byte missing) must
+// not be mistaken for a clean end of input.
+func TestReadHeaderTruncated(t *testing.T) {
+	tests := [][]byte{
+		// High-tag-number form: the 0x1f low bits of the first octet say a
+		// tag-number continuation follows, but nothing does.
+		{0x1f},
+		// Tag read, but the length octet is missing entirely.
+		{0x02},
+		// Tag and a long-form length lead byte read, but the length octets
+		// themselves (2 of them, per 0x82) are missing.
+		{0x04, 0x82},
+	}
+	for _, in := range tests {
+		r := bufio.NewReader(bytes.NewReader(in))
+		if _, _, err := readHeader(r); err != io.ErrUnexpectedEOF {
+			t.Errorf("readHeader(%x) err = %v; want io.ErrUnexpectedEOF", in, err)
+		}
+	}
+}
+
+// TestDerToASCIIStreamTruncatedConstructedBody is a regression test: a
+// constructed element's declared length running past the actual end of
+// input must surface an error, not a silently partial disassembly.
+func TestDerToASCIIStreamTruncatedConstructedBody(t *testing.T) {
+	// SEQUENCE, declared length 6, but only 1 content byte follows.
+	in := []byte{0x30, 0x06, 0x02}
+	var got bytes.Buffer
+	if err := DerToASCIIStream(bytes.NewReader(in), &got); err == nil {
+		t.Error("DerToASCIIStream(truncated) returned nil error; want an error")
+	}
+}
+
+// TestDerToASCIIStreamMatchesDerToASCII checks that, for input with no
+// X.509 structure hints in play, streaming disassembly renders identically
+// to the in-memory implementation.
+func TestDerToASCIIStreamMatchesDerToASCII(t *testing.T) {
+	// SEQUENCE { INTEGER { 1 } INTEGER { 2 } }
+	in := []byte{0x30, 0x06, 0x02, 0x01, 0x01, 0x02, 0x01, 0x02}
+
+	want := derToASCII(in)
+
+	var got bytes.Buffer
+	if err := DerToASCIIStream(bytes.NewReader(in), &got); err != nil {
+		t.Fatalf("DerToASCIIStream: %s", err)
+	}
+	if got.String() != want {
+		t.Errorf("DerToASCIIStream(in) = %q; want %q", got.String(), want)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2015 The DER ASCII Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// quoteRunes renders runes as a quoted literal using the given quote byte.
+// Newlines, the quote byte and backslashes are escaped as
+// bytesToQuotedString escapes them; other printable runes, non-ASCII ones
+// included, are written literally as UTF-8, and the rest as \x{hex}.
+func quoteRunes(runes []rune, quote byte) string {
+	var out bytes.Buffer
+	out.WriteByte(quote)
+	for _, r := range runes {
+		switch {
+		case r == '\n':
+			out.WriteString(`\n`)
+		case r < 0x80 && byte(r) == quote:
+			fmt.Fprintf(&out, `\%c`, quote)
+		case r == '\\':
+			out.WriteString(`\\`)
+		case unicode.IsPrint(r):
+			out.WriteRune(r)
+		default:
+			fmt.Fprintf(&out, `\x{%x}`, r)
+		}
+	}
+	out.WriteByte(quote)
+	return out.String()
+}
+
+// utf8StringToString decodes body as a UTF8String, returning it as a
+// `u"..."` literal understood by ascii2der, or false if body is not
+// well-formed UTF-8.
+func utf8StringToString(body []byte) (string, bool) {
+	if !utf8.Valid(body) {
+		return "", false
+	}
+	return "u" + quoteRunes([]rune(string(body)), '"'), true
+}
+
+// bmpStringToString decodes body as a BMPString (UCS-2, big-endian),
+// returning it as a `b"..."` literal, or false if body is not a whole
+// number of UCS-2 code units.
+func bmpStringToString(body []byte) (string, bool) {
+	if len(body)%2 != 0 {
+		return "", false
+	}
+	runes := make([]rune, 0, len(body)/2)
+	for i := 0; i < len(body); i += 2 {
+		runes = append(runes, rune(uint16(body[i])<<8|uint16(body[i+1])))
+	}
+	return "b" + quoteRunes(runes, '"'), true
+}
+
+// universalStringToString decodes body as a UniversalString (UCS-4,
+// big-endian), returning it as a `U"..."` literal, or false if body is not
+// a whole number of UCS-4 code units.
+func universalStringToString(body []byte) (string, bool) {
+	if len(body)%4 != 0 {
+		return "", false
+	}
+	runes := make([]rune, 0, len(body)/4)
+	for i := 0; i < len(body); i += 4 {
+		r := rune(uint32(body[i])<<24 | uint32(body[i+1])<<16 | uint32(body[i+2])<<8 | uint32(body[i+3]))
+		runes = append(runes, r)
+	}
+	return "U" + quoteRunes(runes, '"'), true
+}
+
+// printableStringAlphabet lists the non-alphanumeric characters allowed in
+// a PrintableString, per X.680 41.4.
+const printableStringAlphabet = " '()+,-./:=?"
+
+// isPrintableString reports whether body consists only of characters in
+// the PrintableString alphabet.
+func isPrintableString(body []byte) bool {
+	for _, b := range body {
+		isAlnum := b >= 'A' && b <= 'Z' || b >= 'a' && b <= 'z' || b >= '0' && b <= '9'
+		if !isAlnum && !strings.ContainsRune(printableStringAlphabet, rune(b)) {
+			return false
+		}
+	}
+	return true
+}
+
+// isIA5String reports whether body consists only of IA5 (ASCII) characters.
+func isIA5String(body []byte) bool {
+	for _, b := range body {
+		if b >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+// Copyright 2015 The DER ASCII Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestUTF8StringToString(t *testing.T) {
+	if s, ok := utf8StringToString([]byte("héllo")); !ok || s != `u"héllo"` {
+		t.Errorf("utf8StringToString(héllo) = %q, %v; want u\"héllo\", true", s, ok)
+	}
+	if _, ok := utf8StringToString([]byte{0xff, 0xfe}); ok {
+		t.Error("utf8StringToString accepted invalid UTF-8")
+	}
+}
+
+func TestBMPStringToString(t *testing.T) {
+	// U+0041 U+0042, big-endian UCS-2.
+	if s, ok := bmpStringToString([]byte{0x00, 0x41, 0x00, 0x42}); !ok || s != `b"AB"` {
+		t.Errorf("bmpStringToString(AB) = %q, %v; want b\"AB\", true", s, ok)
+	}
+	if _, ok := bmpStringToString([]byte{0x00}); ok {
+		t.Error("bmpStringToString accepted an odd-length body")
+	}
+}
+
+func TestUniversalStringToString(t *testing.T) {
+	// U+0041 U+0042, big-endian UCS-4.
+	body := []byte{0, 0, 0, 0x41, 0, 0, 0, 0x42}
+	if s, ok := universalStringToString(body); !ok || s != `U"AB"` {
+		t.Errorf("universalStringToString(AB) = %q, %v; want U\"AB\", true", s, ok)
+	}
+	if _, ok := universalStringToString([]byte{0, 0, 0}); ok {
+		t.Error("universalStringToString accepted a body not a multiple of 4 bytes")
+	}
+}
+
+func TestIsPrintableString(t *testing.T) {
+	if !isPrintableString([]byte("Example Org, Inc.")) {
+		t.Error("isPrintableString rejected a valid PrintableString")
+	}
+	if isPrintableString([]byte("under_score")) {
+		t.Error("isPrintableString accepted '_', which is not in the PrintableString alphabet")
+	}
+}
+
+func TestIsIA5String(t *testing.T) {
+	if !isIA5String([]byte("user@example.com")) {
+		t.Error("isIA5String rejected a valid IA5String")
+	}
+	if isIA5String([]byte{0xe9}) {
+		t.Error("isIA5String accepted a non-ASCII byte")
+	}
+}
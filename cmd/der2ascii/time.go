@@ -0,0 +1,64 @@
+// Copyright 2015 The DER ASCII Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// utcTimeLayouts and generalizedTimeLayouts are the Go time layouts
+// accepted for UTCTime and GeneralizedTime bodies, per X.680 46/47 and
+// X.690 11.7/11.8: YYMMDDHHMM[SS]Z and YYYYMMDDHHMMSS[.fff]Z respectively,
+// in each case optionally with a numeric timezone offset instead of "Z".
+// "Z0700" is Go's layout token for "Z, or a numeric offset".
+var utcTimeLayouts = []string{
+	"060102150405Z0700",
+	"0601021504Z0700",
+}
+
+var generalizedTimeLayouts = []string{
+	"20060102150405.999Z0700",
+	"20060102150405Z0700",
+}
+
+// parseASN1Time parses s, the body of a UTCTime or GeneralizedTime element,
+// per the layouts above.
+func parseASN1Time(s string, generalized bool) (time.Time, bool) {
+	layouts := utcTimeLayouts
+	if generalized {
+		layouts = generalizedTimeLayouts
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// formatTimeLiteral renders body, the content of a UTCTime or
+// GeneralizedTime element, as its original quoted literal followed by a
+// human-readable comment, e.g. `"230115120000Z" # 2023-01-15 12:00:00
+// UTC`, so certificate validity periods can be read at a glance. If body
+// does not parse, it falls back to bytesToHexString, same as other
+// primitive types whose heuristics fail.
+func formatTimeLiteral(body []byte, generalized bool) string {
+	t, ok := parseASN1Time(string(body), generalized)
+	if !ok {
+		return bytesToHexString(body)
+	}
+	return fmt.Sprintf("%s # %s", bytesToQuotedString(body), t.UTC().Format("2006-01-02 15:04:05 UTC"))
+}
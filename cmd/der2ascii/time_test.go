@@ -0,0 +1,52 @@
+// Copyright 2015 The DER ASCII Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestParseASN1Time(t *testing.T) {
+	tests := []struct {
+		s           string
+		generalized bool
+		ok          bool
+	}{
+		{"230115120000Z", false, true},
+		{"2301151200Z", false, true},
+		{"bogus", false, false},
+		{"20230115120000Z", true, true},
+		{"20230115120000.5Z", true, true},
+		{"bogus", true, false},
+	}
+	for _, tt := range tests {
+		if _, ok := parseASN1Time(tt.s, tt.generalized); ok != tt.ok {
+			t.Errorf("parseASN1Time(%q, %v) ok = %v; want %v", tt.s, tt.generalized, ok, tt.ok)
+		}
+	}
+}
+
+func TestFormatTimeLiteral(t *testing.T) {
+	got := formatTimeLiteral([]byte("230115120000Z"), false)
+	want := `"230115120000Z" # 2023-01-15 12:00:00 UTC`
+	if got != want {
+		t.Errorf("formatTimeLiteral(UTCTime) = %q; want %q", got, want)
+	}
+
+	// A body that does not parse as a time falls back to hex, same as
+	// other primitive types whose heuristics fail.
+	got = formatTimeLiteral([]byte("not a time"), false)
+	if got != bytesToHexString([]byte("not a time")) {
+		t.Errorf("formatTimeLiteral(invalid) = %q; want hex fallback", got)
+	}
+}
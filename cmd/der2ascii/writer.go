@@ -17,7 +17,9 @@ package main
 import (
 	"bytes"
 	"encoding/hex"
+	"encoding/pem"
 	"fmt"
+	"io"
 	"strconv"
 	"unicode"
 
@@ -154,18 +156,30 @@ func objectIdentifierToString(in []byte) string {
 	return out.String()
 }
 
-func addLine(out *bytes.Buffer, indent int, value string) {
+func addLine(out io.Writer, indent int, value string) {
 	for i := 0; i < indent; i++ {
-		out.WriteString("  ")
+		io.WriteString(out, "  ")
 	}
-	out.WriteString(value)
-	out.WriteString("\n")
+	io.WriteString(out, value)
+	io.WriteString(out, "\n")
 }
 
 // derToASCIIImpl disassembles in and writes the result to out with the given
 // indent. If stopAtEOC is true, it will stop after an end-of-contents marker
-// and return the remaining unprocessed bytes of in.
-func derToASCIIImpl(out *bytes.Buffer, in []byte, indent int, stopAtEOC bool) []byte {
+// and return the remaining unprocessed bytes of in. out need not be backed
+// by memory: any io.Writer works, which lets callers like
+// DerToASCIIStream write lines as they are produced instead of buffering
+// the whole disassembly.
+func derToASCIIImpl(out io.Writer, in []byte, indent int, stopAtEOC bool) []byte {
+	// hint, when non-nil, is a structure decoder registered against the
+	// OID most recently seen as a sibling element, to be applied to the
+	// next non-empty element that is not itself a BOOLEAN (the optional "critical"
+	// flag of an X.509 Extension). This turns pairs like
+	// { extnID, critical?, extnValue } or { algorithm, parameters } into
+	// deterministic, labeled output instead of the generic heuristics
+	// below.
+	var hint structureDecoder
+
 	for len(in) != 0 {
 		if stopAtEOC && len(in) >= 2 && in[0] == 0 && in[1] == 0 {
 			// Emit a `0000` in lieu of a closing base.
@@ -194,64 +208,125 @@ func derToASCIIImpl(out *bytes.Buffer, in []byte, indent int, stopAtEOC bool) []
 			continue
 		}
 
+		name, _, _ := tag.GetAlias()
+
+		if hint != nil && name != "BOOLEAN" {
+			// This is the element the preceding OID told us how to
+			// decode: an extnValue or algorithm parameters. The decoder owns its own opening/closing
+			// line(s), since not every hinted element is a container
+			// (an id-ecPublicKey's parameters, for instance, are a
+			// bare OBJECT_IDENTIFIER).
+			dec := hint
+			hint = nil
+			dec(tag, body, out, indent)
+			continue
+		}
+
 		if tag.Constructed {
 			// If the element is constructed, recurse.
 			addLine(out, indent, fmt.Sprintf("%s {", tagToString(tag)))
 			derToASCIIImpl(out, body, indent+1, false)
 			addLine(out, indent, "}")
-		} else {
-			// The element is primitive. By default, emit the body
-			// on the same line as curly braces. However, in some
-			// cases, we heuristically decode the body as DER too.
-			// In this case, the newlines are inserted as in the
-			// constructed case.
+			continue
+		}
+
+		// The element is primitive.
+		hint = writePrimitive(out, tag, name, body, indent)
+	}
+	return nil
+}
 
-			// If ok is false, name will be empty. There is also no
-			// need to check toggleConstructed as we already know
-			// the tag is primitive.
-			name, _, _ := tag.GetAlias()
-			switch name {
-			case "INTEGER":
-				addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), integerToString(body)))
-			case "OBJECT_IDENTIFIER":
-				if name, ok := objectIdentifierToName(body); ok {
-					addLine(out, indent, fmt.Sprintf("# %s", name))
-				}
-				addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), objectIdentifierToString(body)))
-			case "BOOLEAN":
-				var encoded string
-				if len(body) == 1 && body[0] == 0x00 {
-					encoded = "FALSE"
-				} else if len(body) == 1 && body[0] == 0xff {
-					encoded = "TRUE"
-				} else {
-					encoded = bytesToHexString(body)
-				}
-				addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), encoded))
-			case "BIT_STRING":
-				// X.509 encodes signatures and SPKIs in BIT
-				// STRINGs, so there is a 0 phase byte followed
-				// by the potentially DER-encoded structure.
-				if len(body) > 1 && body[0] == 0 && isMadeOfElements(body[1:]) {
-					addLine(out, indent, fmt.Sprintf("%s {", tagToString(tag)))
-					// Emit the phase byte.
-					addLine(out, indent+1, "`00`")
-					// Emit the remaining as a DER element.
-					derToASCIIImpl(out, body[1:], indent+1, false) // Adds a trailing newline.
-					addLine(out, indent, "}")
-				} else {
-					addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), bytesToString(body)))
-				}
-			default:
-				// Keep parsing if the body looks like ASN.1.
-				if isMadeOfElements(body) {
-					addLine(out, indent, fmt.Sprintf("%s {", tagToString(tag)))
-					derToASCIIImpl(out, body, indent+1, false)
-					addLine(out, indent, "}")
-				} else {
-					addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), bytesToString(body)))
-				}
-			}
+// writePrimitive writes the line(s) for a primitive element with the
+// given tag, alias name (from tag.GetAlias(), possibly empty), and body.
+// By default, it emits the body on the same line as curly braces; in some
+// cases, it heuristically decodes the body further, in which case
+// newlines are inserted as in the constructed case.
+//
+// If tag is an OBJECT_IDENTIFIER registered in structureHints, the
+// decoder for the *next* sibling element is returned so the caller's loop
+// can carry it across iterations; other callers (e.g. DerToASCIIStream,
+// which does not support X.509 structure hints) may ignore the result.
+func writePrimitive(out io.Writer, tag lib.Tag, name string, body []byte, indent int) structureDecoder {
+	switch name {
+	case "INTEGER":
+		addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), integerToString(body)))
+	case "OBJECT_IDENTIFIER":
+		if oidName, ok := objectIdentifierToName(body); ok {
+			addLine(out, indent, fmt.Sprintf("# %s", oidName))
+		}
+		oid := objectIdentifierToString(body)
+		addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), oid))
+		return structureHints[oid]
+	case "BOOLEAN":
+		var encoded string
+		if len(body) == 1 && body[0] == 0x00 {
+			encoded = "FALSE"
+		} else if len(body) == 1 && body[0] == 0xff {
+			encoded = "TRUE"
+		} else {
+			encoded = bytesToHexString(body)
+		}
+		addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), encoded))
+	case "BIT_STRING":
+		// X.509 encodes signatures and SPKIs in BIT
+		// STRINGs, so there is a 0 phase byte followed
+		// by the potentially DER-encoded structure.
+		if len(body) > 1 && body[0] == 0 && isMadeOfElements(body[1:]) {
+			addLine(out, indent, fmt.Sprintf("%s {", tagToString(tag)))
+			// Emit the phase byte.
+			addLine(out, indent+1, "`00`")
+			// Emit the remaining as a DER element.
+			derToASCIIImpl(out, body[1:], indent+1, false) // Adds a trailing newline.
+			addLine(out, indent, "}")
+		} else {
+			addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), bytesToString(body)))
+		}
+	case "UTF8_STRING":
+		if s, ok := utf8StringToString(body); ok {
+			addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), s))
+		} else {
+			addLine(out, indent, "# invalid UTF-8")
+			addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), bytesToHexString(body)))
+		}
+	case "BMP_STRING":
+		if s, ok := bmpStringToString(body); ok {
+			addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), s))
+		} else {
+			addLine(out, indent, "# invalid BMPString (odd length)")
+			addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), bytesToHexString(body)))
+		}
+	case "UNIVERSAL_STRING":
+		if s, ok := universalStringToString(body); ok {
+			addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), s))
+		} else {
+			addLine(out, indent, "# invalid UniversalString (length not a multiple of 4)")
+			addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), bytesToHexString(body)))
+		}
+	case "PRINTABLE_STRING":
+		if !isPrintableString(body) {
+			addLine(out, indent, "# invalid PrintableString")
+		}
+		addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), bytesToQuotedString(body)))
+	case "IA5_STRING":
+		if !isIA5String(body) {
+			addLine(out, indent, "# invalid IA5String")
+		}
+		addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), bytesToQuotedString(body)))
+	case "TELETEX_STRING":
+		addLine(out, indent, "# T61")
+		addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), bytesToHexString(body)))
+	case "UTCTime":
+		addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), formatTimeLiteral(body, false)))
+	case "GeneralizedTime":
+		addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), formatTimeLiteral(body, true)))
+	default:
+		// Keep parsing if the body looks like ASN.1.
+		if isMadeOfElements(body) {
+			addLine(out, indent, fmt.Sprintf("%s {", tagToString(tag)))
+			derToASCIIImpl(out, body, indent+1, false)
+			addLine(out, indent, "}")
+		} else {
+			addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), bytesToString(body)))
 		}
 	}
 	return nil
@@ -262,3 +337,37 @@ func derToASCII(in []byte) string {
 	derToASCIIImpl(&out, in, 0, false)
 	return out.String()
 }
+
+// looksLikePEM reports whether in begins with a PEM armor header, modulo
+// leading whitespace.
+func looksLikePEM(in []byte) bool {
+	return bytes.HasPrefix(bytes.TrimLeft(in, "\r\n\t "), []byte("-----BEGIN "))
+}
+
+// derToASCIIPEM behaves like derToASCII, except it first checks whether in
+// is PEM-armored. If so, it strips the armor from each PEM block in turn,
+// disassembles the contained DER, and emits the block's label as a leading
+// comment so multi-object PEM streams (e.g. certificate chains) round-trip
+// through a single disassembly. If in is not PEM-armored, it is passed to
+// derToASCII unchanged.
+func derToASCIIPEM(in []byte) string {
+	if !looksLikePEM(in) {
+		return derToASCII(in)
+	}
+
+	var out bytes.Buffer
+	rest := in
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if out.Len() != 0 {
+			out.WriteString("\n")
+		}
+		fmt.Fprintf(&out, "# PEM: %s\n", block.Type)
+		out.WriteString(derToASCII(block.Bytes))
+	}
+	return out.String()
+}
@@ -0,0 +1,394 @@
+// Copyright 2015 The DER ASCII Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/google/der-ascii/lib"
+)
+
+// structureDecoder decodes a whole element (tag and body) whose structure
+// is implied by a preceding sibling OID (an X.509 extnID or an
+// AlgorithmIdentifier's algorithm OID), writing disassembled ASCII to out
+// at the given indent. It owns the element's own opening/closing line(s)
+// -- derToASCIIImpl does not wrap its output -- because not every hinted
+// element is a container: an id-ecPublicKey AlgorithmIdentifier's
+// parameters, for instance, are themselves a primitive OBJECT_IDENTIFIER,
+// not a SEQUENCE. Third parties can register additional decoders in
+// structureHints.
+type structureDecoder func(tag lib.Tag, body []byte, out io.Writer, indent int)
+
+// structureHints maps the dotted string form of an OID, as produced by
+// objectIdentifierToString, to the decoder for the sibling element that
+// follows it. This covers the common X.509 v3 extensions and
+// AlgorithmIdentifier parameter shapes that users actually work with; any
+// OID not in this map falls back to the generic heuristics in
+// derToASCIIImpl.
+var structureHints = map[string]structureDecoder{
+	// X.509 v3 extensions (RFC 5280), keyed by extnID. The extnValue is an
+	// OCTET STRING whose contents are themselves DER-encoded per the
+	// extension; decode that inner encoding directly.
+	"2.5.29.15":               decodeKeyUsageExtension,
+	"2.5.29.17":               decodeSubjectAltNameExtension,
+	"2.5.29.19":               decodeBasicConstraintsExtension,
+	"2.5.29.31":               decodeCRLDistributionPointsExtension,
+	"2.5.29.37":               decodeExtKeyUsageExtension,
+	"1.3.6.1.5.5.7.1.1":       decodeAuthorityInfoAccessExtension,
+	"1.3.6.1.4.1.11129.2.4.2": decodeSCTListExtension,
+
+	// AlgorithmIdentifier parameters, keyed by the algorithm OID.
+	"1.2.840.113549.1.1.10": decodeRSAPSSParams, // RSASSA-PSS
+	"1.2.840.10045.2.1":     decodeECCurve,       // id-ecPublicKey
+}
+
+// wrapContainer writes tag's opening line, runs fill to write its
+// children, and writes the closing line. It is the shared shape used by
+// the decoders below whose hinted element really is a container.
+func wrapContainer(out io.Writer, tag lib.Tag, indent int, fill func(childIndent int)) {
+	addLine(out, indent, fmt.Sprintf("%s {", tagToString(tag)))
+	fill(indent + 1)
+	addLine(out, indent, "}")
+}
+
+// decodeECCurve decodes the parameters of an id-ecPublicKey
+// AlgorithmIdentifier: a primitive OBJECT_IDENTIFIER naming the curve, not
+// a container, so it is rendered as a single line rather than wrapped.
+func decodeECCurve(tag lib.Tag, body []byte, out io.Writer, indent int) {
+	if name, ok := objectIdentifierToName(body); ok {
+		addLine(out, indent, fmt.Sprintf("# %s", name))
+	}
+	addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), objectIdentifierToString(body)))
+}
+
+// rsaPSSParamLabels names the context-tagged fields of RSASSA-PSS-params
+// (RFC 4055 3.1): hashAlgorithm [0], maskGenAlgorithm [1], saltLength [2],
+// trailerField [3], all OPTIONAL with DEFAULTs.
+var rsaPSSParamLabels = map[uint32]string{
+	0: "hashAlgorithm",
+	1: "maskGenAlgorithm",
+	2: "saltLength",
+	3: "trailerField",
+}
+
+// decodeRSAPSSParams decodes the parameters of an RSASSA-PSS
+// AlgorithmIdentifier, labeling each of its optional fields.
+func decodeRSAPSSParams(tag lib.Tag, body []byte, out io.Writer, indent int) {
+	wrapContainer(out, tag, indent, func(childIndent int) {
+		rest := body
+		for len(rest) != 0 {
+			t, v, _, r, ok := parseElement(rest)
+			if !ok {
+				addLine(out, childIndent, bytesToString(rest))
+				return
+			}
+			if t.Class == lib.ClassContextSpecific {
+				if label, ok := rsaPSSParamLabels[t.Number]; ok {
+					addLine(out, childIndent, fmt.Sprintf("# %s", label))
+				}
+			}
+			wrapContainer(out, t, childIndent, func(grandchildIndent int) {
+				derToASCIIImpl(out, v, grandchildIndent, false)
+			})
+			rest = r
+		}
+	})
+}
+
+// decodeKeyUsageExtension decodes the extnValue of a KeyUsage extension,
+// whose inner encoding is a single BIT STRING, labeling the named bits
+// that are actually set.
+func decodeKeyUsageExtension(tag lib.Tag, body []byte, out io.Writer, indent int) {
+	bitTag, bits, _, _, ok := parseElement(body)
+	if !ok || bitTag.Class != lib.ClassUniversal || bitTag.Number != 3 /* BIT STRING */ || len(bits) == 0 {
+		addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), bytesToHexString(body)))
+		return
+	}
+	unused := int(bits[0])
+	names := []string{"digitalSignature", "nonRepudiation", "keyEncipherment",
+		"dataEncipherment", "keyAgreement", "keyCertSign", "cRLSign",
+		"encipherOnly", "decipherOnly"}
+	var set []string
+	for i, name := range names {
+		byteIdx, bitIdx := i/8, 7-i%8
+		if byteIdx+1 >= len(bits) {
+			break
+		}
+		if bits[byteIdx+1]&(1<<uint(bitIdx)) != 0 {
+			set = append(set, name)
+		}
+	}
+	wrapContainer(out, tag, indent, func(childIndent int) {
+		addLine(out, childIndent, fmt.Sprintf("# %v", set))
+		addLine(out, childIndent, fmt.Sprintf("%s { `%02x` %s }", tagToString(bitTag), unused, bytesToHexString(bits[1:])))
+	})
+}
+
+// decodeBasicConstraintsExtension decodes the extnValue of a
+// BasicConstraints extension: SEQUENCE { cA BOOLEAN DEFAULT FALSE,
+// pathLenConstraint INTEGER OPTIONAL }, labeling both fields with a
+// leading comment.
+func decodeBasicConstraintsExtension(tag lib.Tag, body []byte, out io.Writer, indent int) {
+	cA := false
+	pathLen := -1
+	// body is the extnValue's DER encoding, a SEQUENCE; the fields are
+	// inside it.
+	fields := body
+	if t, v, _, _, ok := parseElement(body); ok && t.Number == 16 /* SEQUENCE */ && t.Constructed {
+		fields = v
+	}
+	if t, v, _, r, ok := parseElement(fields); ok && t.Number == 1 /* BOOLEAN */ {
+		cA = len(v) == 1 && v[0] != 0x00
+		if t, v, _, _, ok := parseElement(r); ok && t.Number == 2 /* INTEGER */ {
+			if n, ok := decodeInteger(v); ok {
+				pathLen = int(n)
+			}
+		}
+	} else if t, v, _, _, ok := parseElement(fields); ok && t.Number == 2 /* INTEGER */ {
+		if n, ok := decodeInteger(v); ok {
+			pathLen = int(n)
+		}
+	}
+
+	comment := fmt.Sprintf("# CA:%v", cA)
+	if pathLen >= 0 {
+		comment += fmt.Sprintf(", pathLen:%d", pathLen)
+	}
+	addLine(out, indent, comment)
+	wrapContainer(out, tag, indent, func(childIndent int) {
+		derToASCIIImpl(out, body, childIndent, false)
+	})
+}
+
+// generalNameLabels maps the GeneralName CHOICE context tag numbers (RFC
+// 5280 4.2.1.6) to a human label.
+var generalNameLabels = map[uint32]string{
+	0: "otherName",
+	1: "rfc822Name",
+	2: "dNSName",
+	3: "x400Address",
+	4: "directoryName",
+	5: "ediPartyName",
+	6: "uniformResourceIdentifier",
+	7: "iPAddress",
+	8: "registeredID",
+}
+
+// writeGeneralName writes a single already-parsed GeneralName as a labeled
+// line: the primitive string forms (rfc822Name, dNSName,
+// uniformResourceIdentifier, iPAddress, registeredID) that make up the
+// overwhelming majority of real certificates are quoted directly; the
+// constructed forms (otherName, x400Address, directoryName, ediPartyName)
+// fall back to generic recursion.
+func writeGeneralName(out io.Writer, tag lib.Tag, body []byte, indent int) {
+	label, known := generalNameLabels[tag.Number]
+	if !known || tag.Class != lib.ClassContextSpecific {
+		addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), bytesToString(body)))
+		return
+	}
+	addLine(out, indent, fmt.Sprintf("# %s", label))
+	if tag.Constructed {
+		wrapContainer(out, tag, indent, func(childIndent int) {
+			derToASCIIImpl(out, body, childIndent, false)
+		})
+		return
+	}
+	addLine(out, indent, fmt.Sprintf("%s { %s }", tagToString(tag), bytesToQuotedString(body)))
+}
+
+// decodeSubjectAltNameExtension decodes the extnValue of a SubjectAltName
+// extension: SEQUENCE OF GeneralName.
+func decodeSubjectAltNameExtension(tag lib.Tag, body []byte, out io.Writer, indent int) {
+	// body is the extnValue's DER encoding, a single SEQUENCE: keep its
+	// line and decode the GeneralNames inside it.
+	if seqTag, seqBody, _, seqRest, ok := parseElement(body); ok && tag.Number == 4 /* OCTET STRING */ && seqTag.Number == 16 /* SEQUENCE */ && seqTag.Constructed && len(seqRest) == 0 {
+		wrapContainer(out, tag, indent, func(childIndent int) {
+			decodeSubjectAltNameExtension(seqTag, seqBody, out, childIndent)
+		})
+		return
+	}
+	wrapContainer(out, tag, indent, func(childIndent int) {
+		rest := body
+		for len(rest) != 0 {
+			t, v, _, r, ok := parseElement(rest)
+			if !ok {
+				addLine(out, childIndent, bytesToString(rest))
+				return
+			}
+			writeGeneralName(out, t, v, childIndent)
+			rest = r
+		}
+	})
+}
+
+// decodeExtKeyUsageExtension decodes the extnValue of an ExtKeyUsage
+// extension: SEQUENCE OF KeyPurposeId (OBJECT_IDENTIFIER), labeling each
+// one by name where known.
+func decodeExtKeyUsageExtension(tag lib.Tag, body []byte, out io.Writer, indent int) {
+	// body is the extnValue's DER encoding, a single SEQUENCE: keep its
+	// line and decode the KeyPurposeIds inside it.
+	if seqTag, seqBody, _, seqRest, ok := parseElement(body); ok && tag.Number == 4 /* OCTET STRING */ && seqTag.Number == 16 /* SEQUENCE */ && seqTag.Constructed && len(seqRest) == 0 {
+		wrapContainer(out, tag, indent, func(childIndent int) {
+			decodeExtKeyUsageExtension(seqTag, seqBody, out, childIndent)
+		})
+		return
+	}
+	wrapContainer(out, tag, indent, func(childIndent int) {
+		rest := body
+		for len(rest) != 0 {
+			t, v, _, r, ok := parseElement(rest)
+			if !ok || t.Number != 6 /* OBJECT_IDENTIFIER */ {
+				addLine(out, childIndent, bytesToString(rest))
+				return
+			}
+			if name, ok := objectIdentifierToName(v); ok {
+				addLine(out, childIndent, fmt.Sprintf("# %s", name))
+			}
+			addLine(out, childIndent, fmt.Sprintf("%s { %s }", tagToString(t), objectIdentifierToString(v)))
+			rest = r
+		}
+	})
+}
+
+// accessMethodNames gives the common names of the AuthorityInfoAccess
+// accessMethod OIDs (RFC 5280 4.2.2.1).
+var accessMethodNames = map[string]string{
+	"1.3.6.1.5.5.7.48.1": "id-ad-ocsp",
+	"1.3.6.1.5.5.7.48.2": "id-ad-caIssuers",
+}
+
+// decodeAuthorityInfoAccessExtension decodes the extnValue of an
+// AuthorityInfoAccess extension: SEQUENCE OF AccessDescription {
+// accessMethod OBJECT_IDENTIFIER, accessLocation GeneralName }, labeling
+// the access method and decoding its GeneralName location.
+func decodeAuthorityInfoAccessExtension(tag lib.Tag, body []byte, out io.Writer, indent int) {
+	// body is the extnValue's DER encoding, a single SEQUENCE: keep its
+	// line and decode the AccessDescriptions inside it.
+	if seqTag, seqBody, _, seqRest, ok := parseElement(body); ok && tag.Number == 4 /* OCTET STRING */ && seqTag.Number == 16 /* SEQUENCE */ && seqTag.Constructed && len(seqRest) == 0 {
+		wrapContainer(out, tag, indent, func(childIndent int) {
+			decodeAuthorityInfoAccessExtension(seqTag, seqBody, out, childIndent)
+		})
+		return
+	}
+	wrapContainer(out, tag, indent, func(childIndent int) {
+		rest := body
+		for len(rest) != 0 {
+			t, v, _, r, ok := parseElement(rest)
+			if !ok || !t.Constructed {
+				addLine(out, childIndent, bytesToString(rest))
+				return
+			}
+			wrapContainer(out, t, childIndent, func(grandchildIndent int) {
+				methodTag, methodOID, _, methodRest, ok := parseElement(v)
+				if !ok || methodTag.Number != 6 /* OBJECT_IDENTIFIER */ {
+					derToASCIIImpl(out, v, grandchildIndent, false)
+					return
+				}
+				oid := objectIdentifierToString(methodOID)
+				if name, ok := accessMethodNames[oid]; ok {
+					addLine(out, grandchildIndent, fmt.Sprintf("# %s", name))
+				}
+				addLine(out, grandchildIndent, fmt.Sprintf("%s { %s }", tagToString(methodTag), oid))
+				if locTag, locBody, _, _, ok := parseElement(methodRest); ok {
+					writeGeneralName(out, locTag, locBody, grandchildIndent)
+				}
+			})
+			rest = r
+		}
+	})
+}
+
+// decodeCRLDistributionPointsExtension decodes the extnValue of a
+// CRLDistributionPoints extension: SEQUENCE OF DistributionPoint. It
+// labels the fullName GeneralNames inside each DistributionPoint's [0]
+// distributionPoint field and falls back to generic disassembly for the
+// less commonly populated reasons/cRLIssuer fields.
+func decodeCRLDistributionPointsExtension(tag lib.Tag, body []byte, out io.Writer, indent int) {
+	// body is the extnValue's DER encoding, a single SEQUENCE: keep its
+	// line and decode the DistributionPoints inside it.
+	if seqTag, seqBody, _, seqRest, ok := parseElement(body); ok && tag.Number == 4 /* OCTET STRING */ && seqTag.Number == 16 /* SEQUENCE */ && seqTag.Constructed && len(seqRest) == 0 {
+		wrapContainer(out, tag, indent, func(childIndent int) {
+			decodeCRLDistributionPointsExtension(seqTag, seqBody, out, childIndent)
+		})
+		return
+	}
+	wrapContainer(out, tag, indent, func(childIndent int) {
+		rest := body
+		for len(rest) != 0 {
+			t, v, _, r, ok := parseElement(rest)
+			if !ok || !t.Constructed {
+				addLine(out, childIndent, bytesToString(rest))
+				return
+			}
+			wrapContainer(out, t, childIndent, func(grandchildIndent int) {
+				writeDistributionPoint(out, v, grandchildIndent)
+			})
+			rest = r
+		}
+	})
+}
+
+// writeDistributionPoint decodes the body of a single DistributionPoint,
+// recursing into its [0] distributionPoint field to label any fullName
+// GeneralNames.
+func writeDistributionPoint(out io.Writer, body []byte, indent int) {
+	rest := body
+	for len(rest) != 0 {
+		t, v, _, r, ok := parseElement(rest)
+		if !ok {
+			addLine(out, indent, bytesToString(rest))
+			return
+		}
+		if t.Class == lib.ClassContextSpecific && t.Number == 0 && t.Constructed {
+			// distributionPoint [0] DistributionPointName
+			wrapContainer(out, t, indent, func(childIndent int) {
+				nameTag, nameBody, _, _, ok := parseElement(v)
+				if !ok || nameTag.Class != lib.ClassContextSpecific || nameTag.Number != 0 || !nameTag.Constructed {
+					derToASCIIImpl(out, v, childIndent, false)
+					return
+				}
+				// fullName [0] GeneralNames
+				wrapContainer(out, nameTag, childIndent, func(grandchildIndent int) {
+					gnRest := nameBody
+					for len(gnRest) != 0 {
+						gt, gv, _, gr, ok := parseElement(gnRest)
+						if !ok {
+							addLine(out, grandchildIndent, bytesToString(gnRest))
+							return
+						}
+						writeGeneralName(out, gt, gv, grandchildIndent)
+						gnRest = gr
+					}
+				})
+			})
+		} else {
+			derToASCIIImpl(out, rest[:len(rest)-len(r)], indent, false)
+		}
+		rest = r
+	}
+}
+
+// decodeSCTListExtension decodes the extnValue of a CT Precertificate SCTs
+// / X509v3 Certificate Transparency SCT list extension, which wraps a
+// length-prefixed TLS-style list inside an OCTET STRING. der-ascii has no
+// native TLS-list notation, so this is rendered as hex with a comment
+// rather than as DER elements.
+func decodeSCTListExtension(tag lib.Tag, body []byte, out io.Writer, indent int) {
+	wrapContainer(out, tag, indent, func(childIndent int) {
+		addLine(out, childIndent, "# SCT list (TLS-encoded, not DER)")
+		addLine(out, childIndent, bytesToHexString(body))
+	})
+}
@@ -0,0 +1,65 @@
+// Copyright 2015 The DER ASCII Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/der-ascii/lib"
+)
+
+// TestDecodeECCurveNoDoubleWrap is a regression test for a bug where the
+// id-ecPublicKey parameters decoder wrapped its single OBJECT_IDENTIFIER
+// line in its own container on top of the one derToASCIIImpl's dispatch
+// loop had already opened, producing two nested sets of braces around a
+// value that is not itself a container.
+func TestDecodeECCurveNoDoubleWrap(t *testing.T) {
+	// AlgorithmIdentifier { OBJECT_IDENTIFIER { id-ecPublicKey }
+	//                       OBJECT_IDENTIFIER { prime256v1 } }
+	// 06 07 2A 86 48 CE 3D 02 01  -- 1.2.840.10045.2.1 (id-ecPublicKey)
+	// 06 08 2A 86 48 CE 3D 03 01 07 -- 1.2.840.10045.3.1.7 (prime256v1)
+	in := []byte{
+		0x30, 0x13,
+		0x06, 0x07, 0x2a, 0x86, 0x48, 0xce, 0x3d, 0x02, 0x01,
+		0x06, 0x08, 0x2a, 0x86, 0x48, 0xce, 0x3d, 0x03, 0x01, 0x07,
+	}
+	got := derToASCII(in)
+	if strings.Count(got, "{") != strings.Count(got, "}") {
+		t.Fatalf("derToASCII(EC AlgorithmIdentifier) unbalanced braces: %q", got)
+	}
+	if strings.Count(got, "{\n") != 1 {
+		t.Errorf("derToASCII(EC AlgorithmIdentifier) = %q; want exactly one container, the outer SEQUENCE", got)
+	}
+	if !strings.Contains(got, "1.2.840.10045.3.1.7") {
+		t.Errorf("derToASCII(EC AlgorithmIdentifier) = %q; want the curve OID on its own line", got)
+	}
+}
+
+func TestDecodeKeyUsageExtension(t *testing.T) {
+	// extnValue OCTET STRING wrapping BIT STRING `00 84` (unused=0,
+	// digitalSignature and keyCertSign set).
+	extnValue := []byte{0x03, 0x02, 0x00, 0x84}
+	tag := lib.Tag{Class: lib.ClassUniversal, Constructed: false, Number: 4} // OCTET STRING
+	var out strings.Builder
+	decodeKeyUsageExtension(tag, extnValue, &out, 0)
+	got := out.String()
+	if !strings.Contains(got, "digitalSignature") || !strings.Contains(got, "keyCertSign") {
+		t.Errorf("decodeKeyUsageExtension = %q; want digitalSignature and keyCertSign labeled", got)
+	}
+	if strings.Contains(got, "nonRepudiation") {
+		t.Errorf("decodeKeyUsageExtension = %q; want nonRepudiation unset, not labeled", got)
+	}
+}